@@ -0,0 +1,55 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pprof
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+)
+
+func init() {
+	http.HandleFunc("/debug/pprof/schedstats", Schedstats)
+}
+
+// schedStats is the JSON representation of runtime.SchedStats.States
+// served by Schedstats.
+type schedStats struct {
+	Running  int `json:"running"`
+	Runnable int `json:"runnable"`
+	NonGo    int `json:"nongo"`
+	Blocked  int `json:"blocked"`
+}
+
+// Schedstats responds with the current runtime.SchedStats, as reported
+// by runtime.ReadSchedStats. By default it writes a plain-text report;
+// requests with "?debug=json" or an "Accept: application/json" header
+// get a JSON document instead.
+//
+// NOTE: This is part of the same experimental, locally patched
+// SchedStats feature as runtime.ReadSchedStats; see runtime/pstats.go.
+func Schedstats(w http.ResponseWriter, r *http.Request) {
+	var s runtime.SchedStats
+	runtime.ReadSchedStats(&s, runtime.SchedStatsStates)
+	stats := schedStats{
+		Running:  s.States.Running,
+		Runnable: s.States.Runnable,
+		NonGo:    s.States.NonGo,
+		Blocked:  s.States.Blocked,
+	}
+
+	if r.FormValue("debug") == "json" || r.Header.Get("Accept") == "application/json" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(stats)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "running: %d\n", stats.Running)
+	fmt.Fprintf(w, "runnable: %d\n", stats.Runnable)
+	fmt.Fprintf(w, "nongo: %d\n", stats.NonGo)
+	fmt.Fprintf(w, "blocked: %d\n", stats.Blocked)
+}
@@ -0,0 +1,60 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+// A Description describes a runtime metric.
+type Description struct {
+	// Name is the name of the metric, including its unit, in the
+	// form "/a/b/c:unit".
+	Name string
+
+	// Description is an English-language sentence describing the
+	// metric.
+	Description string
+
+	// Kind is the kind of value for this metric.
+	Kind ValueKind
+}
+
+// numSchedStats is the number of metrics in schedStatsDesc.
+const numSchedStats = 4
+
+// schedStatsDesc describes the metrics backed by runtime.SchedStats.
+var schedStatsDesc = [numSchedStats]Description{
+	{
+		Name:        "/sched/goroutines/running:goroutines",
+		Description: "Approximate number of goroutines actively executing Go code, summed across all Ps.",
+		Kind:        KindUint64,
+	},
+	{
+		Name:        "/sched/goroutines/runnable:goroutines",
+		Description: "Approximate number of goroutines waiting for an OS thread to become available.",
+		Kind:        KindUint64,
+	},
+	{
+		Name:        "/sched/goroutines/nongo:goroutines",
+		Description: "Approximate number of goroutines executing non-Go code, such as system calls.",
+		Kind:        KindUint64,
+	},
+	{
+		Name:        "/sched/goroutines/blocked:goroutines",
+		Description: "Approximate number of goroutines blocked waiting on some event, such as a channel receive or timer.",
+		Kind:        KindUint64,
+	},
+}
+
+// All returns a slice describing all supported metrics.
+func All() []Description {
+	return schedStatsDesc[:]
+}
+
+func schedStatsIndex(name string) int {
+	for i, d := range schedStatsDesc {
+		if d.Name == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,12 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package metrics provides a stable interface to access
+// implementation-defined metrics exported by the Go runtime.
+//
+// NOTE: This is an experimental feature locally patched into Go. Unlike
+// the upstream runtime/metrics package, this copy only exports the
+// scheduler counters backed by runtime.SchedStats (see
+// runtime/pstats.go), under the "/sched/goroutines/..." namespace.
+package metrics
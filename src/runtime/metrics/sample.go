@@ -0,0 +1,82 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import _ "unsafe" // for go:linkname
+
+// ValueKind is a tag for a metric Value which indicates its type.
+type ValueKind int
+
+const (
+	// KindBad indicates that the Value has no type and should be
+	// considered an error.
+	KindBad ValueKind = iota
+
+	// KindUint64 indicates that the type of the Value is a uint64.
+	KindUint64
+)
+
+// Value represents a metric value returned by the runtime.
+type Value struct {
+	kind   ValueKind
+	scalar uint64
+}
+
+// Kind returns the tag representing the kind of value this is.
+func (v Value) Kind() ValueKind {
+	return v.kind
+}
+
+// Uint64 returns the internal uint64 value for the metric.
+//
+// If v.Kind() != KindUint64, this method panics.
+func (v Value) Uint64() uint64 {
+	if v.kind != KindUint64 {
+		panic("metrics: Uint64 called on non-uint64 metric value")
+	}
+	return v.scalar
+}
+
+// Sample captures a single metric sample.
+type Sample struct {
+	// Name is the name of the metric sampled.
+	//
+	// It must correspond to a name in one of the metric descriptions
+	// returned by All.
+	Name string
+
+	// Value is the value of the metric sample.
+	Value Value
+}
+
+//go:linkname runtime_readSchedStatsMetrics runtime/metrics.runtime_readSchedStatsMetrics
+func runtime_readSchedStatsMetrics(out []uint64)
+
+// Read populates each Value field in the given slice of Samples.
+//
+// Reusing the same slice with repeated calls to Read is safe and more
+// efficient than allocating a new slice each time, since it avoids
+// reallocating the same data structure over and over again.
+//
+// Note that re-use has some caveats. First, the Value of a Sample
+// obtained after a call to Read must not be accessed concurrently
+// with a subsequent call to Read. Second, unknown metric names are
+// not an error and simply receive a zero Value.
+func Read(m []Sample) {
+	var vals [numSchedStats]uint64
+	var read bool
+	for i := range m {
+		idx := schedStatsIndex(m[i].Name)
+		if idx < 0 {
+			m[i].Value = Value{}
+			continue
+		}
+		if !read {
+			runtime_readSchedStatsMetrics(vals[:])
+			read = true
+		}
+		m[i].Value = Value{kind: KindUint64, scalar: vals[idx]}
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+import _ "unsafe" // for go:linkname
+
+// schedStatsMetricNames are the runtime/metrics names backed by
+// readSchedStatsStates, in the order their values are written by
+// runtime_readSchedStatsMetrics.
+//
+// NOTE: This is part of the same experimental, locally patched
+// SchedStats feature as runtime.ReadSchedStats; see runtime/pstats.go.
+var schedStatsMetricNames = [...]string{
+	"/sched/goroutines/running:goroutines",
+	"/sched/goroutines/runnable:goroutines",
+	"/sched/goroutines/nongo:goroutines",
+	"/sched/goroutines/blocked:goroutines",
+}
+
+// runtime_readSchedStatsMetrics is linked into runtime/metrics, where
+// it backs the four /sched/goroutines/* samples. It calls
+// readSchedStatsStates at most once no matter how many of the four
+// counters the caller asked for, so reading all of them through
+// runtime/metrics costs no more than a single ReadSchedStats call.
+//
+//go:linkname runtime_readSchedStatsMetrics runtime/metrics.runtime_readSchedStatsMetrics
+func runtime_readSchedStatsMetrics(out []uint64) {
+	var s SchedStats
+	readSchedStatsStates(&s)
+	out[0] = uint64(s.States.Running)
+	out[1] = uint64(s.States.Runnable)
+	out[2] = uint64(s.States.NonGo)
+	out[3] = uint64(s.States.Blocked)
+}
@@ -61,6 +61,60 @@ type SchedStats struct {
 		// timer.
 		Blocked int
 	}
+
+	// History records time-weighted aggregates of States.Runnable,
+	// computed over a rolling window of recent samples. It's
+	// populated when SchedStatsHistory is passed to ReadSchedStats.
+	History struct {
+		// Load1, Load5, and Load15 are exponentially-weighted
+		// moving averages of Runnable over 1s, 5s, and 15s
+		// horizons, analogous to the Unix load average.
+		Load1, Load5, Load15 float64
+
+		// MeanRunnable and MaxRunnable are the mean and maximum
+		// Runnable count observed over the sampled window (up
+		// to schedStatsHistLen * schedStatsHistPeriod).
+		MeanRunnable float64
+		MaxRunnable  int
+
+		// FracRunnableBusy is the fraction, in [0,1], of sampled
+		// time during which Runnable was greater than zero.
+		FracRunnableBusy float64
+	}
+
+	// PerP records each P's own Running/Runnable counts and status,
+	// indexed in the same order as runtime.GOMAXPROCS. It's
+	// populated when SchedStatsPerP is passed to ReadSchedStats.
+	PerP []PStats
+
+	// WaitReasons buckets the goroutines counted in States.Blocked
+	// by their wait reason (e.g. chan receive, select, timer). It's
+	// populated when SchedStatsWaitReasons is passed to
+	// ReadSchedStats.
+	WaitReasons map[WaitReason]int
+}
+
+// A WaitReason identifies why a goroutine is parked. Its string form
+// mirrors the runtime's internal wait reasons (e.g. "chan receive",
+// "select", "sleep").
+type WaitReason string
+
+// A PStats records the state of a single P at the moment SchedStats
+// was read.
+type PStats struct {
+	// ID is the P's id.
+	ID int
+
+	// Status is the P's scheduling status, one of the _P* constants
+	// (e.g. _Prunning, _Psyscall, _Pidle).
+	Status uint32
+
+	// Running is 1 if the P is in _Prunning, else 0.
+	Running int
+
+	// Runnable is the number of goroutines on this P's local run
+	// queue, including runnext.
+	Runnable int
 }
 
 // SchedStatsFlags controls the behavior of ReadSchedStats.
@@ -70,6 +124,18 @@ const (
 	// SchedStatsStates indicates that ReadSchedStats should fill
 	// the SchedStats.States field.
 	SchedStatsStates SchedStatsFlags = 1 << iota
+
+	// SchedStatsHistory indicates that ReadSchedStats should fill
+	// the SchedStats.History field.
+	SchedStatsHistory
+
+	// SchedStatsPerP indicates that ReadSchedStats should fill the
+	// SchedStats.PerP field.
+	SchedStatsPerP
+
+	// SchedStatsWaitReasons indicates that ReadSchedStats should
+	// fill the SchedStats.WaitReasons field.
+	SchedStatsWaitReasons
 )
 
 // ReadSchedStats populates s with scheduler statistics.
@@ -86,6 +152,15 @@ func ReadSchedStats(s *SchedStats, flags SchedStatsFlags) {
 	if flags&SchedStatsStates != 0 {
 		readSchedStatsStates(s)
 	}
+	if flags&SchedStatsHistory != 0 {
+		readSchedStatsHistory(s)
+	}
+	if flags&SchedStatsPerP != 0 {
+		readSchedStatsPerP(s)
+	}
+	if flags&SchedStatsWaitReasons != 0 {
+		readSchedStatsWaitReasons(s)
+	}
 }
 
 func readSchedStatsStates(s *SchedStats) {
@@ -117,20 +192,7 @@ retry:
 			goto retry
 		}
 
-		for {
-			h := atomic.Load(&p.runqhead)
-			t := atomic.Load(&p.runqtail)
-			next := atomic.Loaduintptr((*uintptr)(&p.runnext))
-			runnable := int32(t - h)
-			if atomic.Load(&p.runqhead) != h || runnable < 0 {
-				continue
-			}
-			if next != 0 {
-				runnable++
-			}
-			ss.Runnable += int(runnable)
-			break
-		}
+		ss.Runnable += int(runqLen(p))
 	}
 
 	// Global run queue.
@@ -154,3 +216,21 @@ retry:
 
 	unlock(&sched.lock)
 }
+
+// runqLen returns the number of goroutines queued on p's local run
+// queue, including runnext.
+func runqLen(p *p) int32 {
+	for {
+		h := atomic.Load(&p.runqhead)
+		t := atomic.Load(&p.runqtail)
+		next := atomic.Loaduintptr((*uintptr)(&p.runnext))
+		runnable := int32(t - h)
+		if atomic.Load(&p.runqhead) != h || runnable < 0 {
+			continue
+		}
+		if next != 0 {
+			runnable++
+		}
+		return runnable
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+import (
+	"runtime/internal/atomic"
+	"unsafe"
+)
+
+// A SchedState identifies one of the four scheduling states tracked
+// by SchedStats.
+type SchedState int
+
+const (
+	SchedRunning SchedState = iota
+	SchedRunnable
+	SchedNonGo
+	SchedBlocked
+)
+
+// A SchedEvent records a single goroutine transitioning into or out
+// of one of the states tracked by SchedStats.
+type SchedEvent struct {
+	// G is an opaque token identifying the goroutine that
+	// transitioned. It is stable for the lifetime of the goroutine
+	// but carries no meaning beyond equality comparison.
+	G uintptr
+
+	// P is an opaque token identifying the P the goroutine was
+	// associated with at the time of the event, or 0 if none.
+	P uintptr
+
+	// State is the state being entered, if Enter is true, or left,
+	// if Enter is false.
+	State SchedState
+
+	// Enter is true if the goroutine is entering State, false if it
+	// is leaving it.
+	Enter bool
+
+	// When is the approximate nanotime() of the transition.
+	When int64
+}
+
+// schedEventPollPeriod is the interval at which the watcher goroutine
+// re-scans allgs for state changes.
+const schedEventPollPeriod = 10 * 1000 * 1000 // 10ms
+
+var schedEventsLock mutex
+var schedEventSubs = make(map[int]func(SchedEvent))
+var schedEventNextSubID int
+var schedEventWatcherStarted uint32
+
+// SubscribeSchedEvents registers fn to be called, from a dedicated
+// watcher goroutine, whenever a goroutine is observed transitioning
+// into or out of Running, Runnable, NonGo, or Blocked. It returns a
+// cancel function that unregisters fn; the caller is responsible for
+// calling cancel once it no longer wants events.
+//
+// Events are detected by diffing successive scans of allgs taken
+// every schedEventPollPeriod, rather than by hooking the scheduler's
+// internal transition points (casgstatus, acquirep, releasep), so
+// delivery lags a real transition by up to one poll period and can
+// miss a state that round-trips entirely between polls. Callers that
+// need exact, zero-latency transition events should continue to use
+// the execution tracer; this API trades that precision for much
+// lower overhead and no tracer dependency.
+//
+// fn must not block or do expensive work, since it runs inline on the
+// shared watcher goroutine and will delay every other subscriber and
+// every pending event if it does.
+//
+// NOTE: This is an experimental feature locally patched into Go.
+// It is not part of the standard Go release.
+func SubscribeSchedEvents(fn func(SchedEvent)) (cancel func()) {
+	lock(&schedEventsLock)
+	id := schedEventNextSubID
+	schedEventNextSubID++
+	schedEventSubs[id] = fn
+	unlock(&schedEventsLock)
+
+	if atomic.Cas(&schedEventWatcherStarted, 0, 1) {
+		go schedEventWatcher()
+	}
+
+	var canceled uint32
+	return func() {
+		if !atomic.Cas(&canceled, 0, 1) {
+			return
+		}
+		lock(&schedEventsLock)
+		delete(schedEventSubs, id)
+		unlock(&schedEventsLock)
+	}
+}
+
+// classifyG maps gp's current status to the SchedState SchedStats
+// would count it under, if any.
+func classifyG(gp *g) (state SchedState, ok bool) {
+	switch readgstatus(gp) &^ _Gscan {
+	case _Grunning:
+		return SchedRunning, true
+	case _Grunnable:
+		return SchedRunnable, true
+	case _Gsyscall:
+		return SchedNonGo, true
+	case _Gwaiting:
+		return SchedBlocked, true
+	default:
+		return 0, false
+	}
+}
+
+// gSchedP returns the opaque P token to report alongside events for
+// gp, or 0 if gp isn't currently associated with an M holding a P.
+func gSchedP(gp *g) uintptr {
+	if gp.m != nil {
+		if p := gp.m.p.ptr(); p != nil {
+			return uintptr(unsafe.Pointer(p))
+		}
+	}
+	return 0
+}
+
+// schedEventWatcher is the dedicated goroutine started by the first
+// call to SubscribeSchedEvents. It polls allgs every
+// schedEventPollPeriod, diffs against the previous scan, and emits a
+// SchedEvent for every goroutine whose tracked state changed. It idles
+// without scanning whenever there are no live subscribers.
+func schedEventWatcher() {
+	prev := make(map[uintptr]SchedState)
+	for {
+		if !schedEventsHaveSubs() {
+			prev = make(map[uintptr]SchedState)
+			timeSleep(schedEventPollPeriod)
+			continue
+		}
+
+		now := nanotime()
+		seen := make(map[uintptr]SchedState, len(prev))
+		var events []SchedEvent
+
+		// Only collect events here; allglock is non-reentrant, so
+		// subscriber callbacks (which may allocate, start a
+		// goroutine, or otherwise touch allgs) must not run while
+		// it's held. They're emitted below, after unlock.
+		lock(&allglock)
+		for _, gp := range allgs {
+			state, ok := classifyG(gp)
+			if !ok {
+				continue
+			}
+			token := uintptr(unsafe.Pointer(gp))
+			seen[token] = state
+			if old, tracked := prev[token]; !tracked || old != state {
+				p := gSchedP(gp)
+				if tracked {
+					events = append(events, SchedEvent{G: token, P: p, State: old, Enter: false, When: now})
+				}
+				events = append(events, SchedEvent{G: token, P: p, State: state, Enter: true, When: now})
+			}
+		}
+		unlock(&allglock)
+
+		for token, old := range prev {
+			if _, ok := seen[token]; !ok {
+				events = append(events, SchedEvent{G: token, State: old, Enter: false, When: now})
+			}
+		}
+
+		if len(events) > 0 {
+			emitSchedEvents(events)
+		}
+
+		prev = seen
+		timeSleep(schedEventPollPeriod)
+	}
+}
+
+func schedEventsHaveSubs() bool {
+	lock(&schedEventsLock)
+	have := len(schedEventSubs) > 0
+	unlock(&schedEventsLock)
+	return have
+}
+
+// emitSchedEvents delivers events to every subscriber current at the
+// time of the call. It's called with no runtime locks held.
+func emitSchedEvents(events []SchedEvent) {
+	lock(&schedEventsLock)
+	subs := make([]func(SchedEvent), 0, len(schedEventSubs))
+	for _, fn := range schedEventSubs {
+		subs = append(subs, fn)
+	}
+	unlock(&schedEventsLock)
+
+	for _, ev := range events {
+		for _, fn := range subs {
+			fn(ev)
+		}
+	}
+}
+
@@ -0,0 +1,40 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+// readSchedStatsPerP fills s.PerP with one PStats entry per live P.
+func readSchedStatsPerP(s *SchedStats) {
+	lock(&sched.lock)
+	out := make([]PStats, 0, len(allp))
+	for _, p := range allp {
+		if p == nil || p.status == _Pdead {
+			break
+		}
+		ps := PStats{ID: int(p.id), Status: p.status, Runnable: int(runqLen(p))}
+		if p.status == _Prunning {
+			ps.Running = 1
+		}
+		out = append(out, ps)
+	}
+	unlock(&sched.lock)
+
+	s.PerP = out
+}
+
+// readSchedStatsWaitReasons fills s.WaitReasons by walking allgs and
+// bucketing every goroutine parked in _Gwaiting by its wait reason.
+func readSchedStatsWaitReasons(s *SchedStats) {
+	out := make(map[WaitReason]int)
+
+	lock(&allglock)
+	for _, gp := range allgs {
+		if readgstatus(gp)&^_Gscan == _Gwaiting {
+			out[WaitReason(gp.waitreason.String())]++
+		}
+	}
+	unlock(&allglock)
+
+	s.WaitReasons = out
+}